@@ -0,0 +1,541 @@
+// Package promise provides a JavaScript-like Promise[T] for Go, backed by
+// goroutines or a pluggable worker Pool.
+package promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/panjf2000/ants/v2"
+	conc "github.com/sourcegraph/conc/pool"
+)
+
+// Pool submits work to be executed asynchronously. It abstracts over the
+// various worker-pool libraries the package integrates with so that a
+// Promise does not need to know which one backs it.
+type Pool interface {
+	Submit(task func())
+}
+
+// poolFunc adapts a plain function into a Pool.
+type poolFunc func(task func())
+
+func (f poolFunc) Submit(task func()) {
+	f(task)
+}
+
+// newDefaultPool returns the Pool used when none is supplied: one goroutine
+// per submitted task.
+func newDefaultPool() Pool {
+	return poolFunc(func(task func()) {
+		go task()
+	})
+}
+
+// FromAntsPool adapts a panjf2000/ants pool into a Pool.
+func FromAntsPool(pool *ants.Pool) Pool {
+	return poolFunc(func(task func()) {
+		if err := pool.Submit(task); err != nil {
+			go task()
+		}
+	})
+}
+
+// FromAlittoPool adapts an alitto/pond worker pool into a Pool.
+func FromAlittoPool(pool *pond.WorkerPool) Pool {
+	return poolFunc(func(task func()) {
+		pool.Submit(task)
+	})
+}
+
+// FromConcPool adapts a sourcegraph/conc pool into a Pool.
+func FromConcPool(pool *conc.Pool) Pool {
+	return poolFunc(func(task func()) {
+		pool.Go(task)
+	})
+}
+
+// Promise represents a value that will become available at some point,
+// possibly asynchronously, possibly with an error instead.
+type Promise[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	value  T
+	err    error
+}
+
+// New creates a Promise that runs executor on a new goroutine. executor must
+// call resolve exactly once on success or reject exactly once on failure; a
+// panic inside executor is recovered and treated as a rejection. The ctx
+// passed to executor is cancelled as soon as the promise settles, or, for
+// the combinators in this package (Then, Finally, All, Race, AllSettled,
+// Any, Timeout, Retry), as soon as cancelling the combinator's own promise
+// (e.g. because an enclosing Race or Any decided the outcome) cascades down
+// into whatever promise it wraps. Long-running work such as HTTP or DB
+// calls should observe <-ctx.Done() and bail out early.
+func New[T any](executor func(ctx context.Context, resolve func(T), reject func(error))) *Promise[T] {
+	return NewWithPool(executor, newDefaultPool())
+}
+
+// NewWithPool is like New but submits executor to pool instead of spawning a
+// bare goroutine, letting callers bound concurrency. If the promise's
+// context is cancelled before pool gets around to running a queued task,
+// the task drops the work instead of invoking executor and rejects the
+// promise with the context's error.
+func NewWithPool[T any](executor func(ctx context.Context, resolve func(T), reject func(error)), pool Pool) *Promise[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Promise[T]{ctx: ctx, cancel: cancel, done: make(chan struct{})}
+
+	var once sync.Once
+	resolve := func(value T) {
+		once.Do(func() {
+			p.value = value
+			close(p.done)
+			cancel()
+		})
+	}
+	reject := func(err error) {
+		once.Do(func() {
+			p.err = err
+			close(p.done)
+			cancel()
+		})
+	}
+
+	pool.Submit(func() {
+		if err := ctx.Err(); err != nil {
+			reject(err)
+			return
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				if err, ok := r.(error); ok {
+					reject(err)
+					return
+				}
+				reject(errors.New(fmt.Sprint(r)))
+			}
+		}()
+		executor(ctx, resolve, reject)
+	})
+
+	return p
+}
+
+// NewLegacy creates a Promise using the pre-context two-argument executor
+// signature, for callers that have not migrated to the context-aware New.
+func NewLegacy[T any](executor func(resolve func(T), reject func(error))) *Promise[T] {
+	return New(func(_ context.Context, resolve func(T), reject func(error)) {
+		executor(resolve, reject)
+	})
+}
+
+// NewLegacyWithPool is the NewWithPool counterpart of NewLegacy.
+func NewLegacyWithPool[T any](executor func(resolve func(T), reject func(error)), pool Pool) *Promise[T] {
+	return NewWithPool(func(_ context.Context, resolve func(T), reject func(error)) {
+		executor(resolve, reject)
+	}, pool)
+}
+
+// cancelExecutor stops the promise's executor, either because it settled or because
+// a combinator watching it (Race, Any) has already decided the outcome.
+func (p *Promise[T]) cancelExecutor() {
+	p.cancel()
+}
+
+// Await blocks until the promise settles or ctx is done, whichever comes
+// first. On success it returns a pointer to the resolved value; on failure
+// or context cancellation it returns a nil value and the error.
+func (p *Promise[T]) Await(ctx context.Context) (*T, error) {
+	select {
+	case <-p.done:
+		if p.err != nil {
+			return nil, p.err
+		}
+		value := p.value
+		return &value, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// awaitCancelling awaits p under callerCtx like Await, but also watches
+// execCtx: if execCtx is done first, it cancels p's executor and returns
+// execCtx's error, so that cancelling a combinator's own promise (e.g. via
+// Race or Any deciding the outcome) chains through to whatever it wraps.
+func awaitCancelling[T any](execCtx, callerCtx context.Context, p *Promise[T]) (*T, error) {
+	select {
+	case <-p.done:
+		if p.err != nil {
+			return nil, p.err
+		}
+		value := p.value
+		return &value, nil
+	case <-callerCtx.Done():
+		return nil, callerCtx.Err()
+	case <-execCtx.Done():
+		p.cancelExecutor()
+		return nil, execCtx.Err()
+	}
+}
+
+// Then chains a synchronous transformation onto p, returning a new Promise
+// that settles once p has settled and fn has run. If p rejects, the
+// rejection propagates without fn being called. Cancelling the returned
+// promise's executor (e.g. via Race or Any deciding the outcome) cancels p
+// in turn.
+func Then[T, R any](ctx context.Context, p *Promise[T], fn func(value T) (R, error)) *Promise[R] {
+	return New(func(execCtx context.Context, resolve func(R), reject func(error)) {
+		value, err := awaitCancelling(execCtx, ctx, p)
+		if err != nil {
+			reject(err)
+			return
+		}
+		result, err := fn(*value)
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(result)
+	})
+}
+
+// All returns a Promise that resolves with the values of every ps once they
+// have all fulfilled, in the same order, or rejects as soon as any of them
+// rejects. On rejection, every promise in ps has its executor cancelled.
+// Cancelling the returned promise's own executor cancels every ps in turn.
+func All[T any](ctx context.Context, ps ...*Promise[T]) *Promise[[]T] {
+	return New(func(execCtx context.Context, resolve func([]T), reject func(error)) {
+		values := make([]T, len(ps))
+		errs := make(chan error, 1)
+
+		var wg sync.WaitGroup
+		wg.Add(len(ps))
+		for i, p := range ps {
+			go func(i int, p *Promise[T]) {
+				defer wg.Done()
+				value, err := awaitCancelling(execCtx, ctx, p)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					return
+				}
+				values[i] = *value
+			}(i, p)
+		}
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		var err error
+		select {
+		case err = <-errs:
+		case <-done:
+			select {
+			case err = <-errs:
+			default:
+			}
+		}
+		if err != nil {
+			for _, p := range ps {
+				p.cancelExecutor()
+			}
+			reject(err)
+			return
+		}
+		resolve(values)
+	})
+}
+
+// Race returns a Promise that settles as soon as any of ps settles, with
+// that same value or error. If every promise in ps rejects, Race rejects
+// with the error of the first one to settle. Once the outcome is decided,
+// every promise in ps has its executor cancelled. Cancelling the returned
+// promise's own executor cancels every ps in turn.
+func Race[T any](ctx context.Context, ps ...*Promise[T]) *Promise[T] {
+	return New(func(execCtx context.Context, resolve func(T), reject func(error)) {
+		type settlement struct {
+			value *T
+			err   error
+		}
+		results := make(chan settlement, len(ps))
+		for _, p := range ps {
+			go func(p *Promise[T]) {
+				value, err := awaitCancelling(execCtx, ctx, p)
+				results <- settlement{value, err}
+			}(p)
+		}
+
+		result := <-results
+		for _, p := range ps {
+			p.cancelExecutor()
+		}
+		if result.err != nil {
+			reject(result.err)
+			return
+		}
+		resolve(*result.value)
+	})
+}
+
+// Status describes how a Result settled.
+type Status int
+
+const (
+	// Fulfilled means the promise resolved with a value.
+	Fulfilled Status = iota
+	// Rejected means the promise settled with an error.
+	Rejected
+)
+
+func (s Status) String() string {
+	switch s {
+	case Fulfilled:
+		return "fulfilled"
+	case Rejected:
+		return "rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// Result carries the outcome of a single promise as settled by AllSettled:
+// either a Value (Status == Fulfilled) or an Err (Status == Rejected).
+type Result[T any] struct {
+	Status Status
+	Value  T
+	Err    error
+}
+
+// IsFulfilled reports whether the result settled with a value.
+func (r Result[T]) IsFulfilled() bool {
+	return r.Status == Fulfilled
+}
+
+// IsRejected reports whether the result settled with an error.
+func (r Result[T]) IsRejected() bool {
+	return r.Status == Rejected
+}
+
+// AllSettled returns a Promise that resolves once every one of ps has
+// settled, never rejecting itself. Each element of the resolved slice
+// mirrors the corresponding promise in ps, in order. Cancelling the
+// returned promise's own executor cancels every ps in turn.
+func AllSettled[T any](ctx context.Context, ps ...*Promise[T]) *Promise[[]Result[T]] {
+	return New(func(execCtx context.Context, resolve func([]Result[T]), _ func(error)) {
+		results := make([]Result[T], len(ps))
+
+		var wg sync.WaitGroup
+		wg.Add(len(ps))
+		for i, p := range ps {
+			go func(i int, p *Promise[T]) {
+				defer wg.Done()
+				value, err := awaitCancelling(execCtx, ctx, p)
+				if err != nil {
+					results[i] = Result[T]{Status: Rejected, Err: err}
+					return
+				}
+				results[i] = Result[T]{Status: Fulfilled, Value: *value}
+			}(i, p)
+		}
+		wg.Wait()
+
+		resolve(results)
+	})
+}
+
+// AggregateError wraps every error produced when all promises passed to Any
+// reject. It implements Unwrap() []error so errors.Is and errors.As can
+// match against any of the underlying errors.
+type AggregateError struct {
+	Errors []error
+}
+
+func (e *AggregateError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("all promises were rejected: %s", strings.Join(messages, "; "))
+}
+
+func (e *AggregateError) Unwrap() []error {
+	return e.Errors
+}
+
+// Any returns a Promise that resolves with the value of the first promise in
+// ps to fulfill, cancelling the executors of every other promise in ps. It
+// only rejects once every promise in ps has rejected, with an
+// *AggregateError whose Errors are indexed by each promise's position in ps
+// (not by rejection order). Cancelling the returned promise's own executor
+// cancels every ps in turn.
+func Any[T any](ctx context.Context, ps ...*Promise[T]) *Promise[T] {
+	return New(func(execCtx context.Context, resolve func(T), reject func(error)) {
+		settleCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type settlement struct {
+			index int
+			value *T
+			err   error
+		}
+		results := make(chan settlement, len(ps))
+		for i, p := range ps {
+			go func(i int, p *Promise[T]) {
+				value, err := awaitCancelling(execCtx, settleCtx, p)
+				results <- settlement{index: i, value: value, err: err}
+			}(i, p)
+		}
+
+		errs := make([]error, len(ps))
+		for range ps {
+			result := <-results
+			if result.err == nil {
+				cancel()
+				for _, p := range ps {
+					p.cancelExecutor()
+				}
+				resolve(*result.value)
+				return
+			}
+			errs[result.index] = result.err
+		}
+		reject(&AggregateError{Errors: errs})
+	})
+}
+
+// ErrTimeout is the rejection error produced by Timeout when p does not
+// settle within the given duration.
+var ErrTimeout = errors.New("promise: timed out")
+
+// Timeout returns a Promise that mirrors p, except that it rejects with
+// ErrTimeout if p has not settled within d. Either way, p's executor is
+// cancelled once the outcome is decided, including when the returned
+// promise's own executor is cancelled by an enclosing combinator.
+func Timeout[T any](ctx context.Context, p *Promise[T], d time.Duration) *Promise[T] {
+	return New(func(execCtx context.Context, resolve func(T), reject func(error)) {
+		deadlineCtx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		value, err := awaitCancelling(execCtx, deadlineCtx, p)
+		p.cancelExecutor()
+		if err != nil {
+			if deadlineCtx.Err() == context.DeadlineExceeded {
+				reject(ErrTimeout)
+				return
+			}
+			reject(err)
+			return
+		}
+		resolve(*value)
+	})
+}
+
+// RetryPolicy configures how Retry backs off between attempts. Backoff
+// grows as min(MaxBackoff, InitialBackoff * Multiplier^(attempt-1)),
+// perturbed by up to Jitter (0-1) of its value, picked fresh per attempt.
+// A zero MaxBackoff applies no cap.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+	// Retryable reports whether a rejection should be retried. A nil
+	// Retryable retries every error.
+	Retryable func(error) bool
+}
+
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(policy.InitialBackoff) * math.Pow(policy.Multiplier, float64(attempt-1))
+	if max := float64(policy.MaxBackoff); policy.MaxBackoff > 0 && d > max {
+		d = max
+	}
+	if policy.Jitter > 0 {
+		d += d * policy.Jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// Retry calls factory with increasing attempt numbers (starting at 1) until
+// one of its promises fulfills, policy.Retryable rejects the error, policy's
+// attempts are exhausted, or ctx is done. On exhaustion it rejects with the
+// last error, annotated with the number of attempts made. Cancelling the
+// returned promise's own executor cancels the in-flight attempt in turn.
+func Retry[T any](ctx context.Context, factory func(attempt int) *Promise[T], policy RetryPolicy) *Promise[T] {
+	return New(func(execCtx context.Context, resolve func(T), reject func(error)) {
+		if policy.MaxAttempts <= 0 {
+			reject(errors.New("promise: RetryPolicy.MaxAttempts must be positive"))
+			return
+		}
+
+		var lastErr error
+		attempt := 1
+		for ; attempt <= policy.MaxAttempts; attempt++ {
+			if err := ctx.Err(); err != nil {
+				reject(err)
+				return
+			}
+			if err := execCtx.Err(); err != nil {
+				reject(err)
+				return
+			}
+
+			value, err := awaitCancelling(execCtx, ctx, factory(attempt))
+			if err == nil {
+				resolve(*value)
+				return
+			}
+			if execCtx.Err() != nil {
+				reject(execCtx.Err())
+				return
+			}
+			lastErr = err
+
+			if policy.Retryable != nil && !policy.Retryable(err) {
+				break
+			}
+			if attempt == policy.MaxAttempts {
+				break
+			}
+
+			select {
+			case <-time.After(policy.backoff(attempt)):
+			case <-ctx.Done():
+				reject(ctx.Err())
+				return
+			case <-execCtx.Done():
+				reject(execCtx.Err())
+				return
+			}
+		}
+		reject(fmt.Errorf("promise: giving up after %d attempt(s): %w", attempt, lastErr))
+	})
+}
+
+// Finally runs fn once p has settled, regardless of outcome, then forwards
+// p's value or error unchanged. Cancelling the returned promise's executor
+// (e.g. via Race or Any deciding the outcome) cancels p in turn.
+func Finally[T any](ctx context.Context, p *Promise[T], fn func()) *Promise[T] {
+	return New(func(execCtx context.Context, resolve func(T), reject func(error)) {
+		value, err := awaitCancelling(execCtx, ctx, p)
+		fn()
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(*value)
+	})
+}
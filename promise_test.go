@@ -3,6 +3,8 @@ package promise
 import (
 	"context"
 	"errors"
+	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,7 +20,7 @@ var (
 )
 
 func TestNew(t *testing.T) {
-	p := New(func(resolve func(any), _ func(error)) {
+	p := NewLegacy(func(resolve func(any), _ func(error)) {
 		resolve(nil)
 	})
 	require.NotNil(t, p)
@@ -58,7 +60,7 @@ func TestNewWithPool(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			p := NewWithPool(func(resolve func(string), _ func(error)) {
+			p := NewLegacyWithPool(func(resolve func(string), _ func(error)) {
 				resolve(test.name)
 			}, test.pool)
 
@@ -71,7 +73,7 @@ func TestNewWithPool(t *testing.T) {
 }
 
 func TestPromise_Then(t *testing.T) {
-	p1 := New(func(resolve func(string), _ func(error)) {
+	p1 := NewLegacy(func(resolve func(string), _ func(error)) {
 		resolve("Hello, ")
 	})
 	p2 := Then(ctx, p1, func(data string) (string, error) {
@@ -96,7 +98,7 @@ func TestPromise_Then(t *testing.T) {
 }
 
 func TestPromise_Catch(t *testing.T) {
-	p1 := New(func(_ func(any), reject func(error)) {
+	p1 := NewLegacy(func(_ func(any), reject func(error)) {
 		reject(errExpected)
 	})
 
@@ -107,10 +109,10 @@ func TestPromise_Catch(t *testing.T) {
 }
 
 func TestPromise_Panic(t *testing.T) {
-	p1 := New(func(_ func(any), _ func(error)) {
+	p1 := NewLegacy(func(_ func(any), _ func(error)) {
 		panic("random error")
 	})
-	p2 := New(func(_ func(any), _ func(error)) {
+	p2 := NewLegacy(func(_ func(any), _ func(error)) {
 		panic(errExpected)
 	})
 
@@ -126,13 +128,13 @@ func TestPromise_Panic(t *testing.T) {
 }
 
 func TestAll_Happy(t *testing.T) {
-	p1 := New(func(resolve func(string), _ func(error)) {
+	p1 := NewLegacy(func(resolve func(string), _ func(error)) {
 		resolve("one")
 	})
-	p2 := New(func(resolve func(string), _ func(error)) {
+	p2 := NewLegacy(func(resolve func(string), _ func(error)) {
 		resolve("two")
 	})
-	p3 := New(func(resolve func(string), _ func(error)) {
+	p3 := NewLegacy(func(resolve func(string), _ func(error)) {
 		resolve("three")
 	})
 
@@ -145,13 +147,13 @@ func TestAll_Happy(t *testing.T) {
 }
 
 func TestAll_ContainsRejected(t *testing.T) {
-	p1 := New(func(resolve func(string), _ func(error)) {
+	p1 := NewLegacy(func(resolve func(string), _ func(error)) {
 		resolve("one")
 	})
-	p2 := New(func(_ func(string), reject func(error)) {
+	p2 := NewLegacy(func(_ func(string), reject func(error)) {
 		reject(errExpected)
 	})
-	p3 := New(func(resolve func(string), _ func(error)) {
+	p3 := NewLegacy(func(resolve func(string), _ func(error)) {
 		resolve("three")
 	})
 
@@ -164,13 +166,13 @@ func TestAll_ContainsRejected(t *testing.T) {
 }
 
 func TestAll_OnlyRejected(t *testing.T) {
-	p1 := New(func(_ func(any), reject func(error)) {
+	p1 := NewLegacy(func(_ func(any), reject func(error)) {
 		reject(errExpected)
 	})
-	p2 := New(func(_ func(any), reject func(error)) {
+	p2 := NewLegacy(func(_ func(any), reject func(error)) {
 		reject(errExpected)
 	})
-	p3 := New(func(_ func(any), reject func(error)) {
+	p3 := NewLegacy(func(_ func(any), reject func(error)) {
 		reject(errExpected)
 	})
 
@@ -183,11 +185,11 @@ func TestAll_OnlyRejected(t *testing.T) {
 }
 
 func TestRace_Happy(t *testing.T) {
-	p1 := New(func(resolve func(string), _ func(error)) {
+	p1 := NewLegacy(func(resolve func(string), _ func(error)) {
 		time.Sleep(time.Millisecond * 100)
 		resolve("faster")
 	})
-	p2 := New(func(resolve func(string), _ func(error)) {
+	p2 := NewLegacy(func(resolve func(string), _ func(error)) {
 		time.Sleep(time.Millisecond * 500)
 		resolve("slower")
 	})
@@ -201,11 +203,11 @@ func TestRace_Happy(t *testing.T) {
 }
 
 func TestRace_ContainsRejected(t *testing.T) {
-	p1 := New(func(resolve func(any), _ func(error)) {
+	p1 := NewLegacy(func(resolve func(any), _ func(error)) {
 		time.Sleep(time.Millisecond * 100)
 		resolve(nil)
 	})
-	p2 := New(func(_ func(any), reject func(error)) {
+	p2 := NewLegacy(func(_ func(any), reject func(error)) {
 		reject(errExpected)
 	})
 
@@ -217,11 +219,143 @@ func TestRace_ContainsRejected(t *testing.T) {
 	require.Nil(t, val)
 }
 
+func TestAllSettled_MixedSettlement(t *testing.T) {
+	p1 := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("one")
+	})
+	p2 := NewLegacy(func(_ func(string), reject func(error)) {
+		reject(errExpected)
+	})
+	p3 := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("three")
+	})
+
+	p := AllSettled(ctx, p1, p2, p3)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+
+	results := *val
+	require.True(t, results[0].IsFulfilled())
+	require.Equal(t, "one", results[0].Value)
+	require.True(t, results[1].IsRejected())
+	require.ErrorIs(t, results[1].Err, errExpected)
+	require.True(t, results[2].IsFulfilled())
+	require.Equal(t, "three", results[2].Value)
+}
+
+func TestAllSettled_NeverRejects(t *testing.T) {
+	p1 := NewLegacy(func(_ func(any), reject func(error)) {
+		reject(errExpected)
+	})
+	p2 := NewLegacy(func(_ func(any), reject func(error)) {
+		reject(errExpected)
+	})
+
+	p := AllSettled(ctx, p1, p2)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	for _, result := range *val {
+		require.True(t, result.IsRejected())
+		require.ErrorIs(t, result.Err, errExpected)
+	}
+}
+
+func TestAny_Happy(t *testing.T) {
+	p1 := NewLegacy(func(_ func(string), reject func(error)) {
+		reject(errExpected)
+	})
+	p2 := NewLegacy(func(resolve func(string), _ func(error)) {
+		time.Sleep(time.Millisecond * 50)
+		resolve("winner")
+	})
+
+	p := Any(ctx, p1, p2)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "winner", *val)
+}
+
+func TestAny_AllRejected(t *testing.T) {
+	p1 := NewLegacy(func(_ func(any), reject func(error)) {
+		reject(errExpected)
+	})
+	p2 := NewLegacy(func(_ func(any), reject func(error)) {
+		reject(errExpected)
+	})
+
+	p := Any(ctx, p1, p2)
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.Nil(t, val)
+
+	var aggregateErr *AggregateError
+	require.ErrorAs(t, err, &aggregateErr)
+	require.Len(t, aggregateErr.Errors, 2)
+	require.ErrorIs(t, err, errExpected)
+}
+
+func TestAny_AggregateErrorPreservesInputOrder(t *testing.T) {
+	err1 := errors.New("err1")
+	err2 := errors.New("err2")
+	err3 := errors.New("err3")
+
+	p1 := NewLegacy(func(_ func(string), reject func(error)) {
+		time.Sleep(time.Millisecond * 150)
+		reject(err1)
+	})
+	p2 := NewLegacy(func(_ func(string), reject func(error)) {
+		time.Sleep(time.Millisecond * 10)
+		reject(err2)
+	})
+	p3 := NewLegacy(func(_ func(string), reject func(error)) {
+		time.Sleep(time.Millisecond * 50)
+		reject(err3)
+	})
+
+	p := Any(ctx, p1, p2, p3)
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.Nil(t, val)
+
+	var aggregateErr *AggregateError
+	require.ErrorAs(t, err, &aggregateErr)
+	require.Equal(t, []error{err1, err2, err3}, aggregateErr.Errors)
+}
+
+func TestAny_ContextCancelled(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	p1 := NewLegacy(func(resolve func(any), _ func(error)) {
+		time.Sleep(time.Millisecond * 100)
+		resolve(nil)
+	})
+	p2 := NewLegacy(func(resolve func(any), _ func(error)) {
+		time.Sleep(time.Millisecond * 100)
+		resolve(nil)
+	})
+
+	p := Any(cancelledCtx, p1, p2)
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.Nil(t, val)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
 func TestRace_OnlyRejected(t *testing.T) {
-	p1 := New(func(_ func(any), reject func(error)) {
+	p1 := NewLegacy(func(_ func(any), reject func(error)) {
 		reject(errExpected)
 	})
-	p2 := New(func(_ func(any), reject func(error)) {
+	p2 := NewLegacy(func(_ func(any), reject func(error)) {
 		reject(errExpected)
 	})
 
@@ -232,3 +366,347 @@ func TestRace_OnlyRejected(t *testing.T) {
 	require.ErrorIs(t, err, errExpected)
 	require.Nil(t, val)
 }
+
+func TestPromise_ExecutorCancelled(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	const losers = 20
+	ps := make([]*Promise[int], 0, losers+1)
+	ps = append(ps, New(func(_ context.Context, resolve func(int), _ func(error)) {
+		resolve(0)
+	}))
+	for i := 0; i < losers; i++ {
+		ps = append(ps, New(func(ctx context.Context, _ func(int), reject func(error)) {
+			<-ctx.Done()
+			reject(ctx.Err())
+		}))
+	}
+
+	p := Race(ctx, ps...)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, 0, *val)
+
+	require.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= before+2
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestThen_CancelsUpstream(t *testing.T) {
+	leafCancelled := make(chan struct{})
+	leaf := New(func(ctx context.Context, _ func(string), reject func(error)) {
+		<-ctx.Done()
+		close(leafCancelled)
+		reject(ctx.Err())
+	})
+	chained := Then(ctx, leaf, func(value string) (string, error) {
+		return value, nil
+	})
+
+	winner := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("winner")
+	})
+
+	p := Race(ctx, winner, chained)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "winner", *val)
+
+	select {
+	case <-leafCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("leaf behind Then was not cancelled when Race decided the outcome")
+	}
+}
+
+func TestFinally_CancelsUpstream(t *testing.T) {
+	leafCancelled := make(chan struct{})
+	leaf := New(func(ctx context.Context, _ func(string), reject func(error)) {
+		<-ctx.Done()
+		close(leafCancelled)
+		reject(ctx.Err())
+	})
+	chained := Finally(ctx, leaf, func() {})
+
+	winner := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("winner")
+	})
+
+	p := Race(ctx, winner, chained)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "winner", *val)
+
+	select {
+	case <-leafCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("leaf behind Finally was not cancelled when Race decided the outcome")
+	}
+}
+
+func TestAll_CancelsNestedChildren(t *testing.T) {
+	var cancelled int32
+	makeLeaf := func() *Promise[string] {
+		return New(func(ctx context.Context, _ func(string), reject func(error)) {
+			<-ctx.Done()
+			atomic.AddInt32(&cancelled, 1)
+			reject(ctx.Err())
+		})
+	}
+
+	nested := Then(ctx, All(ctx, makeLeaf(), makeLeaf()), func(values []string) (string, error) {
+		return "", nil
+	})
+
+	winner := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("winner")
+	})
+
+	p := Race(ctx, winner, nested)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "winner", *val)
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&cancelled) == 2
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestRace_CancelsNestedChildren(t *testing.T) {
+	var cancelled int32
+	makeLeaf := func() *Promise[string] {
+		return New(func(ctx context.Context, _ func(string), reject func(error)) {
+			<-ctx.Done()
+			atomic.AddInt32(&cancelled, 1)
+			reject(ctx.Err())
+		})
+	}
+
+	nested := Then(ctx, Race(ctx, makeLeaf(), makeLeaf()), func(value string) (string, error) {
+		return value, nil
+	})
+
+	winner := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("winner")
+	})
+
+	p := Race(ctx, winner, nested)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "winner", *val)
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&cancelled) == 2
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestTimeout_CancelsNestedChild(t *testing.T) {
+	leafCancelled := make(chan struct{})
+	leaf := New(func(ctx context.Context, _ func(string), reject func(error)) {
+		<-ctx.Done()
+		close(leafCancelled)
+		reject(ctx.Err())
+	})
+
+	nested := Timeout(ctx, leaf, time.Second)
+
+	winner := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("winner")
+	})
+
+	p := Race(ctx, winner, nested)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "winner", *val)
+
+	select {
+	case <-leafCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("leaf behind Timeout was not cancelled when Race decided the outcome")
+	}
+}
+
+func TestTimeout_Happy(t *testing.T) {
+	p1 := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("done")
+	})
+
+	p := Timeout(ctx, p1, time.Second)
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "done", *val)
+}
+
+func TestTimeout_Exceeded(t *testing.T) {
+	p1 := NewLegacy(func(resolve func(string), _ func(error)) {
+		time.Sleep(time.Millisecond * 200)
+		resolve("too slow")
+	})
+
+	p := Timeout(ctx, p1, time.Millisecond*50)
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Nil(t, val)
+}
+
+func TestRetry_SucceedsAfterRetries(t *testing.T) {
+	var attempts int
+
+	p := Retry(ctx, func(attempt int) *Promise[string] {
+		return NewLegacy(func(resolve func(string), reject func(error)) {
+			attempts++
+			if attempt < 3 {
+				reject(errExpected)
+				return
+			}
+			resolve("ok")
+		})
+	}, RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond * 10,
+		Multiplier:     2,
+	})
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "ok", *val)
+	require.Equal(t, 3, attempts)
+}
+
+func TestRetry_ExhaustsAttempts(t *testing.T) {
+	var attempts int
+
+	p := Retry(ctx, func(int) *Promise[string] {
+		return NewLegacy(func(_ func(string), reject func(error)) {
+			attempts++
+			reject(errExpected)
+		})
+	}, RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond * 10,
+		Multiplier:     2,
+	})
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.ErrorIs(t, err, errExpected)
+	require.Nil(t, val)
+	require.Equal(t, 3, attempts)
+}
+
+func TestRetry_NotRetryable(t *testing.T) {
+	var attempts int
+
+	p := Retry(ctx, func(int) *Promise[string] {
+		return NewLegacy(func(_ func(string), reject func(error)) {
+			attempts++
+			reject(errExpected)
+		})
+	}, RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond * 10,
+		Multiplier:     2,
+		Retryable:      func(error) bool { return false },
+	})
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.ErrorIs(t, err, errExpected)
+	require.Nil(t, val)
+	require.Equal(t, 1, attempts)
+}
+
+func TestRetry_ZeroMaxAttempts(t *testing.T) {
+	var attempts int
+
+	p := Retry(ctx, func(int) *Promise[string] {
+		attempts++
+		return NewLegacy(func(resolve func(string), _ func(error)) {
+			resolve("unreachable")
+		})
+	}, RetryPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond * 10,
+		Multiplier:     2,
+	})
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.Nil(t, val)
+	require.Equal(t, 0, attempts)
+}
+
+func TestRetry_ContextAlreadyCancelled(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	var attempts int
+
+	p := Retry(cancelledCtx, func(int) *Promise[string] {
+		attempts++
+		return NewLegacy(func(resolve func(string), _ func(error)) {
+			resolve("unreachable")
+		})
+	}, RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond * 10,
+		Multiplier:     2,
+	})
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.Nil(t, val)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 0, attempts)
+}
+
+func TestFinally_RunsOnFulfillment(t *testing.T) {
+	var ran bool
+	p1 := NewLegacy(func(resolve func(string), _ func(error)) {
+		resolve("value")
+	})
+
+	p := Finally(ctx, p1, func() { ran = true })
+
+	val, err := p.Await(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, val)
+	require.Equal(t, "value", *val)
+	require.True(t, ran)
+}
+
+func TestFinally_RunsOnRejection(t *testing.T) {
+	var ran bool
+	p1 := NewLegacy(func(_ func(string), reject func(error)) {
+		reject(errExpected)
+	})
+
+	p := Finally(ctx, p1, func() { ran = true })
+
+	val, err := p.Await(ctx)
+	require.Error(t, err)
+	require.ErrorIs(t, err, errExpected)
+	require.Nil(t, val)
+	require.True(t, ran)
+}